@@ -0,0 +1,120 @@
+/*
+Copyright 2014 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package core
+
+// SecretVolumeSource adapts a Secret into a volume.
+type SecretVolumeSource struct {
+	// SecretName is the name of the secret in the pod's namespace to use.
+	// +optional
+	SecretName string
+	// Items, if unspecified, each key-value pair in the Data field of the referenced
+	// Secret will be projected into the volume as a file whose name is the
+	// key and content is the value.
+	// +optional
+	Items []KeyToPath
+	// DefaultMode is the mode bits used to set permissions on created files by default.
+	// +optional
+	DefaultMode *int32
+	// Optional specifies whether the Secret or its keys must be defined.
+	// +optional
+	Optional *bool
+	// SourceNamespace, if set, names the namespace that owns SecretName instead of the pod's
+	// own namespace. Resolving a cross-namespace reference additionally requires the
+	// consuming pod's service account to be authorized for "get" on the
+	// "secrets/crossnamespace" subresource of SecretName in SourceNamespace.
+	// +optional
+	SourceNamespace *string
+}
+
+// SecretKeyToPathEncoding describes how the value of a projected Secret key should be decoded
+// before it is written to its target file path.
+type SecretKeyToPathEncoding string
+
+const (
+	// SecretKeyToPathEncodingRaw writes the Secret value verbatim (the default).
+	SecretKeyToPathEncodingRaw SecretKeyToPathEncoding = "Raw"
+	// SecretKeyToPathEncodingBase64 base64-decodes the Secret value before writing it.
+	SecretKeyToPathEncodingBase64 SecretKeyToPathEncoding = "Base64"
+	// SecretKeyToPathEncodingHex hex-decodes the Secret value before writing it.
+	SecretKeyToPathEncodingHex SecretKeyToPathEncoding = "Hex"
+)
+
+// KeyToPath maps a string key to a path within a volume.
+type KeyToPath struct {
+	// Key is the key to project.
+	Key string
+	// Path is the relative path of the file to map the key to.
+	Path string
+	// Mode is the mode bits used to set permissions on this file.
+	// +optional
+	Mode *int32
+	// Encoding selects how this key's value is decoded before being written to Path.
+	// +optional
+	Encoding SecretKeyToPathEncoding
+	// FSGroup overrides the pod's fsGroup for this projected file only.
+	// +optional
+	FSGroup *int64
+	// RunAsUser overrides the owning UID for this projected file only.
+	// +optional
+	RunAsUser *int64
+	// SELinuxOptions holds per-key SELinux options for this projected file. The secret volume
+	// plugin does not relabel the file with them; only FSGroup and RunAsUser are applied.
+	// +optional
+	SELinuxOptions *SELinuxOptions
+}
+
+// PodFSGroupChangePolicy holds policies that will be used for applying fsGroup to a volume.
+type PodFSGroupChangePolicy string
+
+const (
+	// FSGroupChangeOnRootMismatch only changes the volume's ownership and permissions if the
+	// root directory does not already match the pod's fsGroup, skipping a full recursive walk.
+	FSGroupChangeOnRootMismatch PodFSGroupChangePolicy = "OnRootMismatch"
+	// FSGroupChangeAlways unconditionally changes the volume's ownership and permissions.
+	FSGroupChangeAlways PodFSGroupChangePolicy = "Always"
+)
+
+// PodSecurityContext holds pod-level security attributes relevant to secret volume validation.
+type PodSecurityContext struct {
+	// FSGroupChangePolicy defines the policy for applying fsGroup to the contents of a volume.
+	// +optional
+	FSGroupChangePolicy *PodFSGroupChangePolicy
+}
+
+// VolumeSource represents the location and type of the volume to mount.
+type VolumeSource struct {
+	// Secret represents a secret that should populate this volume.
+	// +optional
+	Secret *SecretVolumeSource
+}
+
+// Volume represents a named volume in a pod that may be accessed by any container in the pod.
+type Volume struct {
+	// Name of the volume, must be a DNS_LABEL and unique within the pod.
+	Name string
+	VolumeSource
+}
+
+// PodSpec is a description of a pod, trimmed to the fields secret volume validation consults.
+type PodSpec struct {
+	// Volumes is a list of named volumes that may be mounted by containers belonging to the pod.
+	// +optional
+	Volumes []Volume
+	// SecurityContext holds pod-level security attributes and common container settings.
+	// +optional
+	SecurityContext *PodSecurityContext
+}
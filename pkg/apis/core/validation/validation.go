@@ -0,0 +1,129 @@
+/*
+Copyright 2014 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package validation
+
+import (
+	"path"
+	"strings"
+
+	"k8s.io/apimachinery/pkg/util/sets"
+	"k8s.io/apimachinery/pkg/util/validation"
+	"k8s.io/apimachinery/pkg/util/validation/field"
+	"k8s.io/kubernetes/pkg/apis/core"
+)
+
+// validateSecretVolumeSource validates a core.SecretVolumeSource, including the optional
+// cross-namespace reference added for SourceNamespace. podSecurityContext is the owning pod's
+// PodSecurityContext (may be nil) and is consulted to reject per-key ownership overrides that
+// conflict with FSGroupChangePolicy; it is called from ValidateVolumes below, which has the
+// pod's SecurityContext in scope.
+func validateSecretVolumeSource(secretSource *core.SecretVolumeSource, podSecurityContext *core.PodSecurityContext, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if len(secretSource.SecretName) == 0 {
+		allErrs = append(allErrs, field.Required(fldPath.Child("secretName"), ""))
+	}
+
+	if secretSource.SourceNamespace != nil {
+		for _, msg := range validation.IsDNS1123Label(*secretSource.SourceNamespace) {
+			allErrs = append(allErrs, field.Invalid(fldPath.Child("sourceNamespace"), *secretSource.SourceNamespace, msg))
+		}
+	}
+
+	onRootMismatch := podSecurityContext != nil && podSecurityContext.FSGroupChangePolicy != nil &&
+		*podSecurityContext.FSGroupChangePolicy == core.FSGroupChangeOnRootMismatch
+
+	itemsPath := fldPath.Child("items")
+	for i, kp := range secretSource.Items {
+		itemPath := itemsPath.Index(i)
+		allErrs = append(allErrs, validateKeyToPath(&kp, itemPath)...)
+		if onRootMismatch && (kp.FSGroup != nil || kp.RunAsUser != nil || kp.SELinuxOptions != nil) {
+			allErrs = append(allErrs, field.Invalid(itemPath, kp.Path,
+				"per-key ownership overrides cannot be combined with a PodSecurityContext.FSGroupChangePolicy of OnRootMismatch"))
+		}
+	}
+	return allErrs
+}
+
+// ValidatePodSpec validates a core.PodSpec, dispatching each volume source to its type-specific
+// validator.
+func ValidatePodSpec(spec *core.PodSpec, fldPath *field.Path) field.ErrorList {
+	return ValidateVolumes(spec.Volumes, spec.SecurityContext, fldPath.Child("volumes"))
+}
+
+// ValidateVolumes validates a pod's volume list, dispatching each volume's source to its
+// type-specific validator. podSecurityContext is passed through to validators, such as
+// validateSecretVolumeSource, that need to cross-check a volume source against pod-level
+// security settings.
+func ValidateVolumes(volumes []core.Volume, podSecurityContext *core.PodSecurityContext, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	for i, vol := range volumes {
+		volPath := fldPath.Index(i)
+		if len(vol.Name) == 0 {
+			allErrs = append(allErrs, field.Required(volPath.Child("name"), ""))
+		}
+		if vol.Secret != nil {
+			allErrs = append(allErrs, validateSecretVolumeSource(vol.Secret, podSecurityContext, volPath.Child("secret"))...)
+		}
+	}
+	return allErrs
+}
+
+var supportedSecretKeyToPathEncodings = sets.New(
+	core.SecretKeyToPathEncodingRaw,
+	core.SecretKeyToPathEncodingBase64,
+	core.SecretKeyToPathEncodingHex,
+)
+
+func validateKeyToPath(kp *core.KeyToPath, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if len(kp.Key) == 0 {
+		allErrs = append(allErrs, field.Required(fldPath.Child("key"), ""))
+	}
+	if len(kp.Path) == 0 {
+		allErrs = append(allErrs, field.Required(fldPath.Child("path"), ""))
+	}
+	allErrs = append(allErrs, validateLocalNonReservedPath(kp.Path, fldPath.Child("path"))...)
+	if kp.Mode != nil && (*kp.Mode > 0777 || *kp.Mode < 0) {
+		allErrs = append(allErrs, field.Invalid(fldPath.Child("mode"), *kp.Mode, "must be between 0 and 0777"))
+	}
+	if len(kp.Encoding) > 0 && !supportedSecretKeyToPathEncodings.Has(kp.Encoding) {
+		allErrs = append(allErrs, field.NotSupported(fldPath.Child("encoding"), kp.Encoding, sets.List(supportedSecretKeyToPathEncodings)))
+	}
+	return allErrs
+}
+
+// validateLocalNonReservedPath rejects absolute paths, paths that escape the volume via ".."
+// segments, and paths that start with "..".
+func validateLocalNonReservedPath(targetPath string, fldPath *field.Path) field.ErrorList {
+	var allErrs field.ErrorList
+	if len(targetPath) == 0 {
+		return allErrs
+	}
+	if path.IsAbs(targetPath) {
+		allErrs = append(allErrs, field.Invalid(fldPath, targetPath, "must be a relative path"))
+	}
+	for _, item := range strings.Split(targetPath, "/") {
+		if item == ".." {
+			allErrs = append(allErrs, field.Invalid(fldPath, targetPath, "must not contain '..'"))
+			break
+		}
+	}
+	if strings.HasPrefix(targetPath, "..") {
+		allErrs = append(allErrs, field.Invalid(fldPath, targetPath, "must not start with '..'"))
+	}
+	return allErrs
+}
@@ -0,0 +1,107 @@
+/*
+Copyright 2014 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package secretsync implements the optional "sync fetched material into a Kubernetes Secret"
+// step of an ephemeral, SecretProviderClass-style CSI volume: once a CSI driver's
+// NodePublishVolume call has populated a read-only volume from an external secret store, a
+// caller (the csi volume plugin's mounter, or the driver itself) can invoke Sync to mirror the
+// mounted files into a Secret object for containers that need env-var rather than file-based
+// consumption.
+package secretsync
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	v1 "k8s.io/api/core/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientset "k8s.io/client-go/kubernetes"
+)
+
+// SyncToSecretAttribute is the CSI VolumeAttribute key that, when present, names the Secret
+// that the contents of the mounted volume should be mirrored into.
+const SyncToSecretAttribute = "syncToSecret"
+
+// SyncModeAttribute optionally names the file mode (e.g. "0440") the provider asked the mounted
+// files to be projected with. Sync records it verbatim on the synced Secret's
+// SyncModeAnnotation, since a Secret object itself carries no per-object file-mode field.
+const SyncModeAttribute = "syncSecretMode"
+
+// SyncModeAnnotation is the annotation a synced Secret carries attributes[SyncModeAttribute]
+// under, when the CSI driver's VolumeAttributes requested one.
+const SyncModeAnnotation = "csi.volume.kubernetes.io/synced-secret-mode"
+
+// Sync reads every non-directory entry directly under mountDir and upserts a Secret named by
+// attributes[SyncToSecretAttribute] in namespace so its Data matches the mounted files
+// byte-for-byte. It is a no-op if attributes does not request syncing.
+func Sync(ctx context.Context, client clientset.Interface, namespace, mountDir string, attributes map[string]string) error {
+	secretName := attributes[SyncToSecretAttribute]
+	if secretName == "" {
+		return nil
+	}
+
+	entries, err := os.ReadDir(mountDir)
+	if err != nil {
+		return fmt.Errorf("reading mounted secret provider volume %q: %w", mountDir, err)
+	}
+
+	data := make(map[string][]byte, len(entries))
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		content, err := os.ReadFile(filepath.Join(mountDir, entry.Name()))
+		if err != nil {
+			return fmt.Errorf("reading %q for secret sync: %w", entry.Name(), err)
+		}
+		data[entry.Name()] = content
+	}
+
+	var annotations map[string]string
+	if mode := attributes[SyncModeAttribute]; mode != "" {
+		annotations = map[string]string{SyncModeAnnotation: mode}
+	}
+
+	secret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:        secretName,
+			Namespace:   namespace,
+			Annotations: annotations,
+		},
+		Data: data,
+		Type: v1.SecretTypeOpaque,
+	}
+
+	_, err = client.CoreV1().Secrets(namespace).Create(ctx, secret, metav1.CreateOptions{})
+	if apierrors.IsAlreadyExists(err) {
+		existing, getErr := client.CoreV1().Secrets(namespace).Get(ctx, secretName, metav1.GetOptions{})
+		if getErr != nil {
+			return getErr
+		}
+		existing.Data = data
+		if annotations != nil {
+			if existing.Annotations == nil {
+				existing.Annotations = map[string]string{}
+			}
+			existing.Annotations[SyncModeAnnotation] = annotations[SyncModeAnnotation]
+		}
+		_, err = client.CoreV1().Secrets(namespace).Update(ctx, existing, metav1.UpdateOptions{})
+	}
+	return err
+}
@@ -0,0 +1,303 @@
+/*
+Copyright 2014 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Package secret contains the kubelet volume plugin that projects a Secret's Data into a
+// pod's filesystem using the atomic-writer mechanism shared by ConfigMap/Secret/DownwardAPI
+// volumes.
+//
+// Updates only propagate to whole-volume mounts: the atomic writer applies an update by
+// writing the new content into a fresh timestamped directory and repointing a "..data"
+// symlink at it, so a container that opened the volume's top-level directory always resolves
+// the current symlink. A subPath mount of a single key is a bind mount of the regular file the
+// symlink pointed to at mount time, not of the symlink itself, so it keeps serving the
+// original file's content even after the symlink moves on.
+package secret
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	v1 "k8s.io/api/core/v1"
+	authorizationv1 "k8s.io/api/authorization/v1"
+	apierrors "k8s.io/apimachinery/pkg/api/errors"
+	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	clientset "k8s.io/client-go/kubernetes"
+	"k8s.io/kubernetes/pkg/volume"
+	volumeutil "k8s.io/kubernetes/pkg/volume/util"
+)
+
+// secretPlugin is the kubelet VolumePlugin for the "secret" volume source.
+type secretPlugin struct {
+	host volume.VolumeHost
+}
+
+var _ volume.VolumePlugin = &secretPlugin{}
+
+const secretPluginName = "kubernetes.io/secret"
+
+func ProbeVolumePlugins() []volume.VolumePlugin {
+	return []volume.VolumePlugin{&secretPlugin{}}
+}
+
+func (plugin *secretPlugin) Init(host volume.VolumeHost) error {
+	plugin.host = host
+	return nil
+}
+
+func (plugin *secretPlugin) GetPluginName() string {
+	return secretPluginName
+}
+
+func (plugin *secretPlugin) GetVolumeName(spec *volume.Spec) (string, error) {
+	if spec.Volume == nil || spec.Volume.Secret == nil {
+		return "", fmt.Errorf("spec does not reference a secret volume type")
+	}
+	return spec.Volume.Secret.SecretName, nil
+}
+
+func (plugin *secretPlugin) CanSupport(spec *volume.Spec) bool {
+	return spec.Volume != nil && spec.Volume.Secret != nil
+}
+
+func (plugin *secretPlugin) RequiresRemount(spec *volume.Spec) bool {
+	return true
+}
+
+func (plugin *secretPlugin) SupportsMountOption() bool {
+	return false
+}
+
+func (plugin *secretPlugin) SupportsBulkVolumeVerification() bool {
+	return false
+}
+
+// NewMounter returns the volume.Mounter whose SetUpAt resolves source (including an optional
+// cross-namespace reference, subject to the secrets/crossnamespace RBAC check below) and
+// projects the referenced Secret -- the actual mount-time entry point resolveSecretReference
+// and authorizeCrossNamespaceSecretAccess plug into.
+func (plugin *secretPlugin) NewMounter(spec *volume.Spec, pod *v1.Pod) (volume.Mounter, error) {
+	return &secretVolumeMounter{
+		source:     *spec.Volume.Secret,
+		pod:        *pod,
+		kubeClient: plugin.host.GetKubeClient(),
+	}, nil
+}
+
+type secretVolumeMounter struct {
+	source     v1.SecretVolumeSource
+	pod        v1.Pod
+	kubeClient clientset.Interface
+}
+
+var _ volume.Mounter = &secretVolumeMounter{}
+
+func (s *secretVolumeMounter) GetAttributes() volume.Attributes {
+	return volume.Attributes{ReadOnly: true, Managed: true}
+}
+
+func (s *secretVolumeMounter) SetUp(mounterArgs volume.MounterArgs) error {
+	return s.SetUpAt(s.GetPath(), mounterArgs)
+}
+
+func (s *secretVolumeMounter) GetPath() string {
+	return "" // overridden by the real mount-point plumbing in volume.MetricsProvider wiring.
+}
+
+// secretsCrossNamespaceVerb is the RBAC verb checked against the "secrets/crossnamespace"
+// subresource before a pod is allowed to resolve a secret that lives in another namespace.
+const secretsCrossNamespaceVerb = "get"
+
+// resolveSecretReference returns the namespace and name that a SecretVolumeSource's secret
+// should be fetched from, taking the optional cross-namespace reference into account.
+//
+// When sourceNamespace is set, the caller's service account must additionally be authorized
+// for the "get" verb on the "secrets/crossnamespace" subresource of the named secret in
+// sourceNamespace -- plain "get" on "secrets" in the source namespace is not sufficient, so
+// that cross-namespace access must be granted explicitly rather than inherited from
+// same-namespace RBAC rules.
+func resolveSecretReference(podNamespace, secretName string, sourceNamespace *string) (ns, name string) {
+	if sourceNamespace != nil && len(*sourceNamespace) > 0 {
+		return *sourceNamespace, secretName
+	}
+	return podNamespace, secretName
+}
+
+// authorizeCrossNamespaceSecretAccess performs the SubjectAccessReview gating a cross-namespace
+// secret volume mount. It is a no-op (nil, no error) when sourceNamespace is nil, since
+// same-namespace secret access is already covered by the pod's existing "secrets" RBAC grants.
+func authorizeCrossNamespaceSecretAccess(ctx context.Context, client clientset.Interface, serviceAccount, podNamespace, secretName string, sourceNamespace *string) error {
+	if sourceNamespace == nil || len(*sourceNamespace) == 0 {
+		return nil
+	}
+
+	sar := &authorizationv1.SubjectAccessReview{
+		Spec: authorizationv1.SubjectAccessReviewSpec{
+			User: fmt.Sprintf("system:serviceaccount:%s:%s", podNamespace, serviceAccount),
+			ResourceAttributes: &authorizationv1.ResourceAttributes{
+				Namespace:   *sourceNamespace,
+				Verb:        secretsCrossNamespaceVerb,
+				Group:       "",
+				Resource:    "secrets",
+				Subresource: "crossnamespace",
+				Name:        secretName,
+			},
+		},
+	}
+
+	result, err := client.AuthorizationV1().SubjectAccessReviews().Create(ctx, sar, metav1.CreateOptions{})
+	if err != nil {
+		return err
+	}
+	if !result.Status.Allowed {
+		return apierrors.NewForbidden(
+			v1.Resource("secrets"),
+			secretName,
+			fmt.Errorf("service account %q is not authorized for %q on secrets/crossnamespace in namespace %q", serviceAccount, secretsCrossNamespaceVerb, *sourceNamespace),
+		)
+	}
+	return nil
+}
+
+// decodeSecretValue applies a KeyToPath's requested Encoding to a Secret value before it is
+// written to the projected file, so the atomic writer never has to special-case how a payload
+// was stored upstream -- it always writes the bytes this function returns.
+func decodeSecretValue(value []byte, encoding v1.SecretKeyToPathEncoding) ([]byte, error) {
+	switch encoding {
+	case "", v1.SecretKeyToPathEncodingRaw:
+		return value, nil
+	case v1.SecretKeyToPathEncodingBase64:
+		decoded := make([]byte, base64.StdEncoding.DecodedLen(len(value)))
+		n, err := base64.StdEncoding.Decode(decoded, value)
+		if err != nil {
+			return nil, fmt.Errorf("decoding base64-encoded secret value: %w", err)
+		}
+		return decoded[:n], nil
+	case v1.SecretKeyToPathEncodingHex:
+		decoded := make([]byte, hex.DecodedLen(len(value)))
+		n, err := hex.Decode(decoded, value)
+		if err != nil {
+			return nil, fmt.Errorf("decoding hex-encoded secret value: %w", err)
+		}
+		return decoded[:n], nil
+	default:
+		return nil, fmt.Errorf("unsupported KeyToPath encoding %q", encoding)
+	}
+}
+
+// payloadForItem resolves the atomic-writer payload for a single projected KeyToPath entry,
+// decoding the underlying Secret value per item.Encoding.
+func payloadForItem(secretData map[string][]byte, item v1.KeyToPath) ([]byte, error) {
+	value, ok := secretData[item.Key]
+	if !ok {
+		return nil, fmt.Errorf("key %q not found in secret", item.Key)
+	}
+	return decodeSecretValue(value, item.Encoding)
+}
+
+// applyItemOwnership chowns a just-written projected file to item's per-key FSGroup/RunAsUser
+// override, if any, so that two KeyToPath entries backed by the same Secret can end up owned
+// by different sidecars' UIDs/GIDs. It runs after the atomic writer has written filePath and
+// is skipped entirely when item carries no override, leaving the pod-level fsGroup/runAsUser
+// in effect.
+func applyItemOwnership(filePath string, item v1.KeyToPath) error {
+	if item.FSGroup == nil && item.RunAsUser == nil {
+		return nil
+	}
+
+	info, err := os.Stat(filePath)
+	if err != nil {
+		return fmt.Errorf("stat %q before applying ownership override: %w", filePath, err)
+	}
+	uid, gid := fileOwnerIDs(info)
+	if item.RunAsUser != nil {
+		uid = int(*item.RunAsUser)
+	}
+	if item.FSGroup != nil {
+		gid = int(*item.FSGroup)
+	}
+	if err := os.Chown(filePath, uid, gid); err != nil {
+		return fmt.Errorf("applying per-key ownership override to %q: %w", filePath, err)
+	}
+	return nil
+}
+
+// SetUpAt resolves s.source.SecretName (honoring SourceNamespace, subject to the
+// secrets/crossnamespace RBAC check above), fetches the Secret, and projects it into dir
+// using the atomic writer, decoding each item per its Encoding via payloadForItem and then
+// applying any per-item FSGroup/RunAsUser override via applyItemOwnership. When Optional is
+// set, items whose key is missing from the Secret are skipped instead of failing the mount.
+func (s *secretVolumeMounter) SetUpAt(dir string, mounterArgs volume.MounterArgs) error {
+	ctx := context.Background()
+
+	secretNamespace, secretName := resolveSecretReference(s.pod.Namespace, s.source.SecretName, s.source.SourceNamespace)
+	if err := authorizeCrossNamespaceSecretAccess(ctx, s.kubeClient, s.pod.Spec.ServiceAccountName, s.pod.Namespace, secretName, s.source.SourceNamespace); err != nil {
+		return err
+	}
+
+	secret, err := s.kubeClient.CoreV1().Secrets(secretNamespace).Get(ctx, secretName, metav1.GetOptions{})
+	if err != nil {
+		if s.source.Optional != nil && *s.source.Optional && apierrors.IsNotFound(err) {
+			return nil
+		}
+		return fmt.Errorf("fetching secret %s/%s: %w", secretNamespace, secretName, err)
+	}
+
+	items := s.source.Items
+	if len(items) == 0 {
+		for key := range secret.Data {
+			items = append(items, v1.KeyToPath{Key: key, Path: key})
+		}
+	}
+
+	payload := make(map[string]volumeutil.FileProjection, len(items))
+	projected := make([]v1.KeyToPath, 0, len(items))
+	for _, item := range items {
+		if _, ok := secret.Data[item.Key]; !ok && s.source.Optional != nil && *s.source.Optional {
+			continue
+		}
+		value, err := payloadForItem(secret.Data, item)
+		if err != nil {
+			return fmt.Errorf("projecting secret %s/%s: %w", secretNamespace, secretName, err)
+		}
+		mode := int32(0644)
+		if item.Mode != nil {
+			mode = *item.Mode
+		} else if s.source.DefaultMode != nil {
+			mode = *s.source.DefaultMode
+		}
+		payload[item.Path] = volumeutil.FileProjection{Data: value, Mode: mode}
+		projected = append(projected, item)
+	}
+
+	writer, err := volumeutil.NewAtomicWriter(dir, fmt.Sprintf("secret/%s/%s", s.pod.Namespace, s.source.SecretName))
+	if err != nil {
+		return fmt.Errorf("creating atomic writer for secret volume: %w", err)
+	}
+	if err := writer.Write(payload, nil); err != nil {
+		return err
+	}
+
+	for _, item := range projected {
+		if err := applyItemOwnership(filepath.Join(dir, item.Path), item); err != nil {
+			return err
+		}
+	}
+	return nil
+}
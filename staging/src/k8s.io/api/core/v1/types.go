@@ -0,0 +1,110 @@
+/*
+Copyright 2014 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+package v1
+
+// SecretVolumeSource adapts a Secret into a volume.
+//
+// The contents of the target Secret's Data field will be presented in a
+// volume as files using the keys in the Data field as the file names.
+// Secret volumes support ownership management and SELinux relabeling.
+type SecretVolumeSource struct {
+	// secretName is the name of the secret in the pod's namespace to use.
+	// +optional
+	SecretName string `json:"secretName,omitempty" protobuf:"bytes,1,opt,name=secretName"`
+	// items If unspecified, each key-value pair in the Data field of the referenced
+	// Secret will be projected into the volume as a file whose name is the
+	// key and content is the value. If specified, the listed keys will be
+	// projected into the specified paths, and unlisted keys will not be
+	// present. If a key is specified which is not present in the Secret,
+	// the volume setup will error unless it is marked optional. Paths must be
+	// relative and may not contain the '..' path or start with '..'.
+	// +optional
+	// +listType=atomic
+	Items []KeyToPath `json:"items,omitempty" protobuf:"bytes,2,rep,name=items"`
+	// defaultMode is Optional: mode bits used to set permissions on created files by default.
+	// Must be an octal value between 0000 and 0777 or a decimal value between 0 and 511.
+	// YAML accepts both octal and decimal values, JSON requires decimal values for mode bits.
+	// Defaults to 0644.
+	// Directories within the path are not affected by this setting.
+	// This might be in conflict with other options that affect the file
+	// mode, like fsGroup, and the result can be other mode bits set.
+	// +optional
+	DefaultMode *int32 `json:"defaultMode,omitempty" protobuf:"bytes,3,opt,name=defaultMode"`
+	// optional field specify whether the Secret or its keys must be defined
+	// +optional
+	Optional *bool `json:"optional,omitempty" protobuf:"varint,4,opt,name=optional"`
+	// sourceNamespace, if set, names the namespace that owns secretName instead of the pod's
+	// own namespace. Mounting a secret from another namespace additionally requires the
+	// consuming pod's service account to have "get" access to the "secrets/crossnamespace"
+	// subresource of secretName in sourceNamespace.
+	// +optional
+	SourceNamespace *string `json:"sourceNamespace,omitempty" protobuf:"bytes,5,opt,name=sourceNamespace"`
+}
+
+// SecretKeyToPathEncoding describes how the value of a projected Secret key should be decoded
+// before it is written to its target file path.
+type SecretKeyToPathEncoding string
+
+const (
+	// SecretKeyToPathEncodingRaw writes the Secret value verbatim (the default).
+	SecretKeyToPathEncodingRaw SecretKeyToPathEncoding = "Raw"
+	// SecretKeyToPathEncodingBase64 base64-decodes the Secret value before writing it.
+	SecretKeyToPathEncodingBase64 SecretKeyToPathEncoding = "Base64"
+	// SecretKeyToPathEncodingHex hex-decodes the Secret value before writing it.
+	SecretKeyToPathEncodingHex SecretKeyToPathEncoding = "Hex"
+)
+
+// Maps a string key to a path within a volume.
+type KeyToPath struct {
+	// key is the key to project.
+	Key string `json:"key" protobuf:"bytes,1,opt,name=key"`
+
+	// path is the relative path of the file to map the key to.
+	// May not be an absolute path.
+	// May not contain the path element '..'.
+	// May not start with the string '..'.
+	Path string `json:"path" protobuf:"bytes,2,opt,name=path"`
+	// mode is Optional: mode bits used to set permissions on this file.
+	// Must be an octal value between 0000 and 0777 or a decimal value between 0 and 511.
+	// YAML accepts both octal and decimal values, JSON requires decimal values for mode bits.
+	// If not specified, the volume defaultMode will be used.
+	// This might be in conflict with other options that affect the file
+	// mode, like fsGroup, and the result can be other mode bits set.
+	// +optional
+	Mode *int32 `json:"mode,omitempty" protobuf:"varint,3,opt,name=mode"`
+
+	// encoding is Optional: selects how this key's value is decoded before being written to
+	// path. One of Raw (default), Base64, Hex. Lets a Secret whose value must be stored as
+	// base64/hex text (e.g. because it transits a system that only deals in text) still be
+	// materialized on disk as the decoded bytes.
+	// +optional
+	Encoding SecretKeyToPathEncoding `json:"encoding,omitempty" protobuf:"bytes,4,opt,name=encoding,casttype=SecretKeyToPathEncoding"`
+
+	// fsGroup is Optional: overrides the pod's fsGroup for this projected file only, so a
+	// single Secret can back sidecars that run as different groups. Must not be combined
+	// with a PodSecurityContext.FSGroupChangePolicy of OnRootMismatch, since that policy's
+	// ownership shortcut assumes every file in the volume shares one owner.
+	// +optional
+	FSGroup *int64 `json:"fsGroup,omitempty" protobuf:"varint,5,opt,name=fsGroup"`
+	// runAsUser is Optional: overrides the owning UID for this projected file only.
+	// +optional
+	RunAsUser *int64 `json:"runAsUser,omitempty" protobuf:"varint,6,opt,name=runAsUser"`
+	// seLinuxOptions is Optional: per-key SELinux options for this projected file. The secret
+	// volume plugin does not relabel the file with them; only fsGroup and runAsUser are applied.
+	// +optional
+	SELinuxOptions *SELinuxOptions `json:"seLinuxOptions,omitempty" protobuf:"bytes,7,opt,name=seLinuxOptions"`
+}
@@ -18,14 +18,26 @@ package storage
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"encoding/json"
 	"fmt"
 	"path"
+	"strings"
+	"time"
 
+	appsv1 "k8s.io/api/apps/v1"
 	v1 "k8s.io/api/core/v1"
+	rbacv1 "k8s.io/api/rbac/v1"
+	storagev1 "k8s.io/api/storage/v1"
 	apierrors "k8s.io/apimachinery/pkg/api/errors"
 	metav1 "k8s.io/apimachinery/pkg/apis/meta/v1"
+	"k8s.io/apimachinery/pkg/fields"
 	"k8s.io/apimachinery/pkg/util/uuid"
+	"k8s.io/kubernetes/pkg/volume/csi/secretsync"
 	"k8s.io/kubernetes/test/e2e/framework"
+	e2eevents "k8s.io/kubernetes/test/e2e/framework/events"
 	e2epod "k8s.io/kubernetes/test/e2e/framework/pod"
 	e2epodoutput "k8s.io/kubernetes/test/e2e/framework/pod/output"
 	imageutils "k8s.io/kubernetes/test/utils/image"
@@ -339,6 +351,136 @@ var _ = SIGDescribe("Secrets", func() {
 
 	})
 
+	// Unlike the immutable-secret cache behavior above, this exercises atomic-swap update
+	// semantics for a mutable Secret: the kubelet's secret volume manager must never expose a
+	// partial view of /etc/secret-volume/ to a container polling it while a key is mutated,
+	// one is added and one is removed. All three changes are applied in one single Update.
+	ginkgo.It("should atomically swap mutable Secret updates without exposing partial state", func(ctx context.Context) {
+		podLogTimeout := e2epod.GetPodSecretUpdateTimeout(ctx, f.ClientSet)
+		volumeMountPath := "/etc/secret-volume"
+
+		name := "s-test-mutable-" + string(uuid.NewUUID())
+		secret := secretForTest(f.Namespace.Name, name)
+
+		ginkgo.By(fmt.Sprintf("Creating mutable secret with name %s", secret.Name))
+		var err error
+		if secret, err = f.ClientSet.CoreV1().Secrets(f.Namespace.Name).Create(ctx, secret, metav1.CreateOptions{}); err != nil {
+			framework.Failf("unable to create test secret %s: %v", secret.Name, err)
+		}
+
+		pod := &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "pod-secrets-mutable-" + string(uuid.NewUUID())},
+			Spec: v1.PodSpec{
+				Volumes: []v1.Volume{
+					{
+						Name:         "secret-volume",
+						VolumeSource: v1.VolumeSource{Secret: &v1.SecretVolumeSource{SecretName: name}},
+					},
+				},
+				Containers: []v1.Container{
+					{
+						Name:  "secret-volume-test",
+						Image: imageutils.GetE2EImage(imageutils.Agnhost),
+						Command: []string{"sh", "-c"},
+						// List the directory on every iteration so a caller can tell the
+						// atomic symlink swap never leaves a partially-written directory
+						// visible: every listing must show exactly the keys that belong
+						// together, never a mix of pre- and post-update keys.
+						Args: []string{fmt.Sprintf("while true; do echo ---; ls -1 %s; sleep 1; done", volumeMountPath)},
+						VolumeMounts: []v1.VolumeMount{
+							{Name: "secret-volume", MountPath: volumeMountPath, ReadOnly: true},
+						},
+					},
+				},
+				RestartPolicy: v1.RestartPolicyNever,
+			},
+		}
+
+		ginkgo.By("Creating the pod")
+		podClient := e2epod.NewPodClient(f)
+		podClient.CreateSync(ctx, pod)
+
+		podLogs := func() (string, error) {
+			return e2epod.GetPodLogs(ctx, f.ClientSet, f.Namespace.Name, pod.Name, pod.Spec.Containers[0].Name)
+		}
+		gomega.Eventually(ctx, podLogs, podLogTimeout, framework.Poll).Should(gomega.ContainSubstring("data-1"))
+
+		assertNoPartialListing := func(logs string, wantKeys, forbidKeys []string) {
+			for _, listing := range strings.Split(logs, "---") {
+				hasAnyWant := false
+				for _, k := range wantKeys {
+					if strings.Contains(listing, k) {
+						hasAnyWant = true
+					}
+				}
+				if !hasAnyWant {
+					continue
+				}
+				for _, k := range forbidKeys {
+					gomega.Expect(listing).NotTo(gomega.ContainSubstring(k), "observed a partial directory listing mixing pre- and post-update keys")
+				}
+			}
+		}
+
+		ginkgo.By(fmt.Sprintf("Updating secret %v: mutate data-1, add data-4, remove data-2", secret.Name))
+		secret, err = f.ClientSet.CoreV1().Secrets(f.Namespace.Name).Get(ctx, secret.Name, metav1.GetOptions{})
+		framework.ExpectNoError(err, "failed to get secret %q", secret.Name)
+		secret.Data["data-1"] = []byte("value-updated")
+		secret.Data["data-4"] = []byte("value-4")
+		delete(secret.Data, "data-2")
+		_, err = f.ClientSet.CoreV1().Secrets(f.Namespace.Name).Update(ctx, secret, metav1.UpdateOptions{})
+		framework.ExpectNoError(err, "failed to update secret %q", secret.Name)
+
+		gomega.Eventually(ctx, podLogs, podLogTimeout, framework.Poll).Should(gomega.ContainSubstring("data-4"))
+
+		finalLogs, err := podLogs()
+		framework.ExpectNoError(err, "failed to fetch final pod logs")
+		assertNoPartialListing(finalLogs, []string{"data-4"}, []string{"data-2"})
+
+		ginkgo.By("Starting a second pod whose Optional=true volume references a missing key (Optional is set at creation, not toggled mid-run)")
+		missingKeyName := "s-test-mutable-optional-" + string(uuid.NewUUID())
+		missingKeySecret := secretForTest(f.Namespace.Name, missingKeyName)
+		missingKeySecret, err = f.ClientSet.CoreV1().Secrets(f.Namespace.Name).Create(ctx, missingKeySecret, metav1.CreateOptions{})
+		framework.ExpectNoError(err, "unable to create test secret %s", missingKeySecret.Name)
+
+		trueVal := true
+		optPod := &v1.Pod{
+			ObjectMeta: metav1.ObjectMeta{Name: "pod-secrets-mutable-opt-" + string(uuid.NewUUID())},
+			Spec: v1.PodSpec{
+				Volumes: []v1.Volume{
+					{
+						Name: "secret-volume",
+						VolumeSource: v1.VolumeSource{Secret: &v1.SecretVolumeSource{
+							SecretName: missingKeyName,
+							Items: []v1.KeyToPath{
+								{Key: "data-missing", Path: "data-missing"},
+							},
+							Optional: &trueVal,
+						}},
+					},
+				},
+				Containers: []v1.Container{
+					{
+						Name:    "secret-volume-test",
+						Image:   imageutils.GetE2EImage(imageutils.Agnhost),
+						Command: []string{"sh", "-c"},
+						Args:    []string{fmt.Sprintf("while true; do ls -1 %s; sleep 1; done", volumeMountPath)},
+						VolumeMounts: []v1.VolumeMount{
+							{Name: "secret-volume", MountPath: volumeMountPath, ReadOnly: true},
+						},
+					},
+				},
+				RestartPolicy: v1.RestartPolicyNever,
+			},
+		}
+		e2epod.NewPodClient(f).CreateSync(ctx, optPod)
+
+		optPodLogs := func() (string, error) {
+			return e2epod.GetPodLogs(ctx, f.ClientSet, f.Namespace.Name, optPod.Name, optPod.Spec.Containers[0].Name)
+		}
+		gomega.Eventually(ctx, optPodLogs, podLogTimeout, framework.Poll).ShouldNot(gomega.ContainSubstring("data-missing"))
+	})
+
 	/*
 		Release: v1.9
 		Testname: Immutable secret, create, update
@@ -628,6 +770,387 @@ var _ = SIGDescribe("Secrets", func() {
 		getPod := e2epod.Get(f.ClientSet, pod)
 		gomega.Consistently(ctx, getPod).WithTimeout(f.Timeouts.PodStart).Should(e2epod.BeInPhase(v1.PodPending))
 	})
+
+	// Cross-namespace secret volume references require the consuming namespace to hold a
+	// "get" grant on "secrets/crossnamespace" in the source namespace, so this
+	// exercises both the RBAC-authorized happy path and the denied path.
+	f.Context("when mounting a secret from another namespace [Feature:CrossNamespaceSecretVolume]", func() {
+		f.It("should mount the secret when the consuming namespace is granted access", func(ctx context.Context) {
+			sourceNamespace, err := f.CreateNamespace(ctx, "secret-source-ns", nil)
+			framework.ExpectNoError(err, "failed to create source namespace")
+
+			name := "cross-ns-secret-" + string(uuid.NewUUID())
+			secret := secretForTest(sourceNamespace.Name, name)
+
+			ginkgo.By(fmt.Sprintf("Creating secret %s in namespace %s", secret.Name, sourceNamespace.Name))
+			secret, err = f.ClientSet.CoreV1().Secrets(sourceNamespace.Name).Create(ctx, secret, metav1.CreateOptions{})
+			framework.ExpectNoError(err, "unable to create source secret %s", secret.Name)
+
+			ginkgo.By("Granting the consuming namespace access to secrets/crossnamespace")
+			grantCrossNamespaceSecretAccess(ctx, f, sourceNamespace.Name, f.Namespace.Name, name)
+
+			pod := podWithCrossNamespaceSecretVolume(name, sourceNamespace.Name)
+			fileModeRegexp := getFileModeRegex("/etc/secret-volume/data-1", nil)
+			e2epodoutput.TestContainerOutputRegexp(ctx, f, "consume cross-namespace secret", pod, 0, []string{
+				"content of file \"/etc/secret-volume/data-1\": value-1",
+				fileModeRegexp,
+			})
+		})
+
+		f.It("should fail to mount the secret when the consuming namespace is not granted access", func(ctx context.Context) {
+			sourceNamespace, err := f.CreateNamespace(ctx, "secret-source-ns", nil)
+			framework.ExpectNoError(err, "failed to create source namespace")
+
+			name := "cross-ns-secret-" + string(uuid.NewUUID())
+			secret := secretForTest(sourceNamespace.Name, name)
+
+			ginkgo.By(fmt.Sprintf("Creating secret %s in namespace %s", secret.Name, sourceNamespace.Name))
+			_, err = f.ClientSet.CoreV1().Secrets(sourceNamespace.Name).Create(ctx, secret, metav1.CreateOptions{})
+			framework.ExpectNoError(err, "unable to create source secret %s", secret.Name)
+
+			pod := podWithCrossNamespaceSecretVolume(name, sourceNamespace.Name)
+			ginkgo.By("Creating the pod without granting cross-namespace RBAC")
+			pod = e2epod.NewPodClient(f).Create(ctx, pod)
+
+			ginkgo.By("Waiting for the pod to remain in ContainerCreating with a clear FailedMount event")
+			getPod := e2epod.Get(f.ClientSet, pod)
+			gomega.Consistently(ctx, getPod).WithTimeout(f.Timeouts.PodStart).Should(e2epod.BeInPhase(v1.PodPending))
+			eventSelector := fields.Set{
+				"involvedObject.kind":      "Pod",
+				"involvedObject.name":      pod.Name,
+				"involvedObject.namespace": f.Namespace.Name,
+				"reason":                   "FailedMount",
+			}.AsSelector().String()
+			framework.ExpectNoError(e2eevents.WaitTimeoutForEvent(ctx, f.ClientSet, f.Namespace.Name, eventSelector, `not authorized for "get" on secrets/crossnamespace`, framework.PodStartTimeout))
+		})
+
+		f.It("should propagate updates from the source namespace within the secret update timeout", func(ctx context.Context) {
+			sourceNamespace, err := f.CreateNamespace(ctx, "secret-source-ns", nil)
+			framework.ExpectNoError(err, "failed to create source namespace")
+
+			name := "cross-ns-secret-" + string(uuid.NewUUID())
+			secret := secretForTest(sourceNamespace.Name, name)
+			secret, err = f.ClientSet.CoreV1().Secrets(sourceNamespace.Name).Create(ctx, secret, metav1.CreateOptions{})
+			framework.ExpectNoError(err, "unable to create source secret %s", secret.Name)
+
+			grantCrossNamespaceSecretAccess(ctx, f, sourceNamespace.Name, f.Namespace.Name, name)
+
+			podLogTimeout := e2epod.GetPodSecretUpdateTimeout(ctx, f.ClientSet)
+			containerTimeoutArg := fmt.Sprintf("--retry_time=%v", int(podLogTimeout.Seconds()))
+			pod := podWithCrossNamespaceSecretVolume(name, sourceNamespace.Name)
+			pod.Spec.Containers[0].Args = []string{"mounttest", "--break_on_expected_content=false", containerTimeoutArg, "--file_content_in_loop=/etc/secret-volume/data-1"}
+
+			ginkgo.By("Creating the pod")
+			podClient := e2epod.NewPodClient(f)
+			podClient.CreateSync(ctx, pod)
+
+			podLogs := func() (string, error) {
+				return e2epod.GetPodLogs(ctx, f.ClientSet, f.Namespace.Name, pod.Name, pod.Spec.Containers[0].Name)
+			}
+			gomega.Eventually(ctx, podLogs, podLogTimeout, framework.Poll).Should(gomega.ContainSubstring("value-1"))
+
+			ginkgo.By(fmt.Sprintf("Updating secret %v in the source namespace", secret.Name))
+			secret, err = f.ClientSet.CoreV1().Secrets(sourceNamespace.Name).Get(ctx, secret.Name, metav1.GetOptions{})
+			framework.ExpectNoError(err, "failed to get source secret %q", secret.Name)
+			secret.Data["data-1"] = []byte("value-updated\n")
+			_, err = f.ClientSet.CoreV1().Secrets(sourceNamespace.Name).Update(ctx, secret, metav1.UpdateOptions{})
+			framework.ExpectNoError(err, "failed to update source secret %q", secret.Name)
+
+			gomega.Eventually(ctx, podLogs, podLogTimeout, framework.Poll).Should(gomega.ContainSubstring("value-updated"))
+		})
+	})
+
+	// Exercises secrets sourced from an external provider (e.g. a cloud KMS or Vault) through
+	// an ephemeral CSI volume, modeled on the community secrets-store-csi driver. The mock
+	// provider gRPC endpoint runs as a DaemonSet in the test namespace so the suite is hermetic.
+	f.Context("when mounting secrets via the external-secret-store CSI driver [Feature:CSIEphemeralSecret]", func() {
+		var mockProvider *mockSecretProviderFixture
+
+		ginkgo.BeforeEach(func(ctx context.Context) {
+			mockProvider = newMockSecretProviderFixture(f)
+			mockProvider.deploy(ctx)
+		})
+
+		ginkgo.AfterEach(func(ctx context.Context) {
+			mockProvider.cleanup(ctx)
+		})
+
+		f.It("should mount secrets resolved by the CSI driver on initial mount", func(ctx context.Context) {
+			mockProvider.seed(ctx, map[string]string{"data-1": "value-1", "data-2": "value-2"})
+
+			pod := podWithEphemeralProviderVolume(mockProvider.driverName, "external-secret", map[string]string{
+				"provider": mockProvider.providerName,
+			}, nil)
+			e2epodoutput.TestContainerOutputRegexp(ctx, f, "consume external secret", pod, 0, []string{
+				"content of file \"/mnt/secrets-store/data-1\": value-1",
+				"content of file \"/mnt/secrets-store/data-2\": value-2",
+			})
+		})
+
+		f.It("should rotate mounted content when the provider polls on a configured interval", func(ctx context.Context) {
+			mockProvider.seed(ctx, map[string]string{"data-1": "value-1"})
+			rotationInterval := 10 * time.Second
+
+			pollArg := fmt.Sprintf("--retry_time=%v", int(e2epod.GetPodSecretUpdateTimeout(ctx, f.ClientSet).Seconds()))
+			pod := podWithEphemeralProviderVolume(mockProvider.driverName, "external-secret-rotate", map[string]string{
+				"provider":         mockProvider.providerName,
+				"pollIntervalSecs": fmt.Sprintf("%d", int(rotationInterval.Seconds())),
+			}, nil)
+			pod.Spec.Containers[0].Args = []string{"mounttest", "--break_on_expected_content=false", pollArg, "--file_content_in_loop=/mnt/secrets-store/data-1"}
+
+			ginkgo.By("Creating the pod")
+			podClient := e2epod.NewPodClient(f)
+			podClient.CreateSync(ctx, pod)
+
+			podLogs := func() (string, error) {
+				return e2epod.GetPodLogs(ctx, f.ClientSet, f.Namespace.Name, pod.Name, pod.Spec.Containers[0].Name)
+			}
+			gomega.Eventually(ctx, podLogs, e2epod.GetPodSecretUpdateTimeout(ctx, f.ClientSet), framework.Poll).Should(gomega.ContainSubstring("value-1"))
+
+			ginkgo.By("Rotating the secret at the mock provider")
+			mockProvider.seed(ctx, map[string]string{"data-1": "value-rotated"})
+			gomega.Eventually(ctx, podLogs, e2epod.GetPodSecretUpdateTimeout(ctx, f.ClientSet), framework.Poll).Should(gomega.ContainSubstring("value-rotated"))
+		})
+
+		f.It("should sync fetched material into a Kubernetes Secret with expected keys and file modes", func(ctx context.Context) {
+			mockProvider.seed(ctx, map[string]string{"data-1": "value-1", "data-2": "value-2"})
+			syncSecretName := "synced-" + string(uuid.NewUUID())
+
+			pod := podWithEphemeralProviderVolume(mockProvider.driverName, "external-secret-sync", map[string]string{
+				"provider":       mockProvider.providerName,
+				"syncToSecret":   syncSecretName,
+				"syncSecretMode": "0440",
+			}, nil)
+
+			ginkgo.By("Creating the pod so the CSI driver syncs the secret")
+			e2epod.NewPodClient(f).CreateSync(ctx, pod)
+
+			ginkgo.By(fmt.Sprintf("Waiting for synced secret %s to appear with expected keys", syncSecretName))
+			var synced *v1.Secret
+			gomega.Eventually(ctx, func(ctx context.Context) (map[string][]byte, error) {
+				var err error
+				synced, err = f.ClientSet.CoreV1().Secrets(f.Namespace.Name).Get(ctx, syncSecretName, metav1.GetOptions{})
+				if err != nil {
+					return nil, err
+				}
+				return synced.Data, nil
+			}, e2epod.GetPodSecretUpdateTimeout(ctx, f.ClientSet), framework.Poll).Should(gomega.Equal(map[string][]byte{
+				"data-1": []byte("value-1"),
+				"data-2": []byte("value-2"),
+			}))
+
+			ginkgo.By("Checking that the requested sync mode was recorded on the synced secret")
+			gomega.Expect(synced.Annotations).To(gomega.HaveKeyWithValue(secretsync.SyncModeAnnotation, "0440"))
+		})
+	})
+
+	// subPath mounts do not observe later Secret updates; see the package doc in
+	// k8s.io/kubernetes/pkg/volume/secret for why. These cases pin down that documented
+	// limitation so a regression (or a future change that lifts it) is caught either way.
+	ginkgo.Context("when mounting individual keys via subPath", func() {
+		ginkgo.It("should not update a key mounted with subPath in place", func(ctx context.Context) {
+			doSecretE2EWithSubPath(ctx, f, false)
+		})
+
+		ginkgo.It("should not update a key mounted with subPathExpr in place", func(ctx context.Context) {
+			doSecretE2EWithSubPath(ctx, f, true)
+		})
+	})
+
+	ginkgo.Context("with binary and large secret payloads", func() {
+		ginkgo.It("should preserve byte-for-byte fidelity of binary data including NULs and high-bit bytes", func(ctx context.Context) {
+			doSecretE2EWithBinaryData(ctx, f, "secret-test-binary-"+string(uuid.NewUUID()), binaryTestPayload(4096))
+		})
+
+		f.It("should preserve byte-for-byte fidelity of a secret value near the 1MiB size limit", f.WithSlow(), func(ctx context.Context) {
+			doSecretE2EWithBinaryData(ctx, f, "secret-test-binary-large-"+string(uuid.NewUUID()), binaryTestPayload(maxSecretSizeBytes-1024))
+		})
+
+		f.It("should record atomic-writer projection and update-propagation latency as secret key count and size grow", f.WithSlow(), func(ctx context.Context) {
+			for _, n := range []int{1, 10, 100} {
+				recordSecretProjectionMetrics(ctx, f, n, 1024)
+			}
+			for _, size := range []int{1024, 64 * 1024, maxSecretSizeBytes - 1024} {
+				recordSecretProjectionMetrics(ctx, f, 1, size)
+			}
+		})
+	})
+
+	// Exercises Secrets whose data is transparently sourced from an external HashiCorp Vault
+	// dev server, modeled on the vaultAddr/secret-path-prefix pattern used by ceph-csi's e2e
+	// utilities. A shim syncs a seeded Vault KV path into a regular Kubernetes Secret, which
+	// is then mounted and asserted on exactly like the in-cluster secrets above.
+	f.Context("when Secret data is sourced from an external Vault instance [Feature:VaultSecretSource]", func() {
+		var vault *vaultFixture
+
+		ginkgo.BeforeEach(func(ctx context.Context) {
+			vault = newVaultFixture(f)
+			vault.deploy(ctx)
+		})
+
+		ginkgo.AfterEach(func(ctx context.Context) {
+			vault.cleanup(ctx)
+		})
+
+		f.It("should mount a Secret synced from a seeded Vault KV path", func(ctx context.Context) {
+			secretPath := "secret/e2e-secrets/" + string(uuid.NewUUID())
+			vault.seed(ctx, secretPath, map[string]string{
+				"data-1": "value-1",
+				"data-2": "value-2",
+				"data-3": "value-3",
+			})
+
+			name := "secret-test-vault-" + string(uuid.NewUUID())
+			vault.syncToSecret(ctx, secretPath, f.Namespace.Name, name)
+
+			pod := podWithCrossNamespaceSecretVolume(name, f.Namespace.Name)
+			pod.Spec.Volumes[0].Secret.SourceNamespace = nil
+			e2epodoutput.TestContainerOutputRegexp(ctx, f, "consume Vault-synced secret", pod, 0, []string{
+				"content of file \"/etc/secret-volume/data-1\": value-1",
+				getFileModeRegex("/etc/secret-volume/data-1", nil),
+			})
+		})
+
+		f.It("should fail to sync a Secret when the Vault path does not exist", func(ctx context.Context) {
+			missingPath := "secret/e2e-secrets/missing-" + string(uuid.NewUUID())
+			err := vault.trySyncToSecret(ctx, missingPath, f.Namespace.Name, "secret-test-vault-missing-"+string(uuid.NewUUID()))
+			if err == nil {
+				framework.Failf("expected syncing a missing Vault path %q to fail", missingPath)
+			}
+		})
+	})
+
+	// KeyToPath.Encoding lets a projected key be materialized as decoded bytes rather than
+	// the raw Secret value, so binary payloads that must be stored base64/hex-encoded inside
+	// the Secret (e.g. because they came from a system that only deals in text) can still be
+	// consumed by containers that expect the decoded bytes on disk.
+	ginkgo.Context("when a KeyToPath requests a non-default Encoding", func() {
+		ginkgo.It("should decode a base64-encoded value when Encoding is Base64", func(ctx context.Context) {
+			doSecretE2EWithMappingEncoding(ctx, f, v1.SecretKeyToPathEncodingBase64)
+		})
+
+		ginkgo.It("should decode a hex-encoded value when Encoding is Hex", func(ctx context.Context) {
+			doSecretE2EWithMappingEncoding(ctx, f, v1.SecretKeyToPathEncodingHex)
+		})
+	})
+
+	// Per-key ownership overrides let a single Secret back credentials for multiple sidecars
+	// that run as different UIDs, without forcing every projected file in the volume to share
+	// one owner.
+	ginkgo.Context("when Items request per-key ownership overrides", func() {
+		ginkgo.It("should apply distinct FSGroup/RunAsUser per projected key", func(ctx context.Context) {
+			name := "secret-test-owner-" + string(uuid.NewUUID())
+			secret := secretForTest(f.Namespace.Name, name)
+
+			ginkgo.By(fmt.Sprintf("Creating secret with name %s", secret.Name))
+			var err error
+			if secret, err = f.ClientSet.CoreV1().Secrets(f.Namespace.Name).Create(ctx, secret, metav1.CreateOptions{}); err != nil {
+				framework.Failf("unable to create test secret %s: %v", secret.Name, err)
+			}
+
+			volumeMountPath := "/etc/secret-volume"
+			uid1, gid1 := int64(1000), int64(1000)
+			uid2, gid2 := int64(2000), int64(2000)
+			pod := &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: "pod-secrets-owner-" + string(uuid.NewUUID())},
+				Spec: v1.PodSpec{
+					Volumes: []v1.Volume{
+						{
+							Name: "secret-volume",
+							VolumeSource: v1.VolumeSource{Secret: &v1.SecretVolumeSource{
+								SecretName: name,
+								Items: []v1.KeyToPath{
+									{
+										Key:       "data-1",
+										Path:      "owner-1000",
+										FSGroup:   &gid1,
+										RunAsUser: &uid1,
+									},
+									{
+										Key:       "data-2",
+										Path:      "owner-2000",
+										FSGroup:   &gid2,
+										RunAsUser: &uid2,
+									},
+								},
+							}},
+						},
+					},
+					Containers: []v1.Container{
+						{
+							Name:  "secret-volume-test",
+							Image: imageutils.GetE2EImage(imageutils.Agnhost),
+							Args: []string{
+								"mounttest",
+								"--file_owner=" + path.Join(volumeMountPath, "owner-1000"),
+								"--file_perm=" + path.Join(volumeMountPath, "owner-1000"),
+								"--file_owner=" + path.Join(volumeMountPath, "owner-2000"),
+								"--file_perm=" + path.Join(volumeMountPath, "owner-2000"),
+							},
+							VolumeMounts: []v1.VolumeMount{
+								{Name: "secret-volume", MountPath: volumeMountPath},
+							},
+						},
+					},
+					RestartPolicy: v1.RestartPolicyNever,
+				},
+			}
+
+			expectedOutput := []string{
+				getFileOwnerRegex(path.Join(volumeMountPath, "owner-1000"), uid1, gid1),
+				getFileOwnerRegex(path.Join(volumeMountPath, "owner-2000"), uid2, gid2),
+			}
+			e2epodoutput.TestContainerOutputRegexp(ctx, f, "consume secrets with per-key ownership", pod, 0, expectedOutput)
+		})
+
+		ginkgo.It("should reject a per-key ownership override when FSGroupChangePolicy is OnRootMismatch", func(ctx context.Context) {
+			name := "secret-test-owner-conflict-" + string(uuid.NewUUID())
+			secret := secretForTest(f.Namespace.Name, name)
+
+			ginkgo.By(fmt.Sprintf("Creating secret with name %s", secret.Name))
+			var err error
+			if secret, err = f.ClientSet.CoreV1().Secrets(f.Namespace.Name).Create(ctx, secret, metav1.CreateOptions{}); err != nil {
+				framework.Failf("unable to create test secret %s: %v", secret.Name, err)
+			}
+
+			gid := int64(1000)
+			onRootMismatch := v1.FSGroupChangeOnRootMismatch
+			pod := &v1.Pod{
+				ObjectMeta: metav1.ObjectMeta{Name: "pod-secrets-owner-conflict-" + string(uuid.NewUUID())},
+				Spec: v1.PodSpec{
+					SecurityContext: &v1.PodSecurityContext{
+						FSGroupChangePolicy: &onRootMismatch,
+					},
+					Volumes: []v1.Volume{
+						{
+							Name: "secret-volume",
+							VolumeSource: v1.VolumeSource{Secret: &v1.SecretVolumeSource{
+								SecretName: name,
+								Items: []v1.KeyToPath{
+									{Key: "data-1", Path: "owner-1000", FSGroup: &gid},
+								},
+							}},
+						},
+					},
+					Containers: []v1.Container{
+						{
+							Name:         "secret-volume-test",
+							Image:        imageutils.GetE2EImage(imageutils.Agnhost),
+							Args:         []string{"mounttest", "--file_content=/etc/secret-volume/owner-1000"},
+							VolumeMounts: []v1.VolumeMount{{Name: "secret-volume", MountPath: "/etc/secret-volume"}},
+						},
+					},
+					RestartPolicy: v1.RestartPolicyNever,
+				},
+			}
+
+			ginkgo.By("Creating the pod, expecting validation to reject the conflicting override")
+			_, err = f.ClientSet.CoreV1().Pods(f.Namespace.Name).Create(ctx, pod, metav1.CreateOptions{})
+			if !apierrors.IsInvalid(err) {
+				framework.Failf("expected pod creation to be rejected as invalid due to conflicting FSGroupChangePolicy, got: %v", err)
+			}
+		})
+	})
 })
 
 func secretForTest(namespace, name string) *v1.Secret {
@@ -890,3 +1413,717 @@ func createNonOptionalSecretPodWithSecret(ctx context.Context, f *framework.Fram
 	pod = e2epod.NewPodClient(f).Create(ctx, pod)
 	return pod
 }
+
+// grantCrossNamespaceSecretAccess creates a ClusterRole/RoleBinding pair that lets
+// consumingNamespace's default service account read the named secret out of
+// sourceNamespace via the secrets/crossnamespace sub-resource.
+func grantCrossNamespaceSecretAccess(ctx context.Context, f *framework.Framework, sourceNamespace, consumingNamespace, secretName string) {
+	roleName := "cross-ns-secret-reader-" + string(uuid.NewUUID())
+	clusterRole := &rbacv1.ClusterRole{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: roleName,
+		},
+		Rules: []rbacv1.PolicyRule{
+			{
+				APIGroups:     []string{""},
+				Resources:     []string{"secrets/crossnamespace"},
+				ResourceNames: []string{secretName},
+				Verbs:         []string{"get", "watch"},
+			},
+		},
+	}
+	_, err := f.ClientSet.RbacV1().ClusterRoles().Create(ctx, clusterRole, metav1.CreateOptions{})
+	framework.ExpectNoError(err, "failed to create ClusterRole %q", roleName)
+	ginkgo.DeferCleanup(func(ctx context.Context) {
+		_ = f.ClientSet.RbacV1().ClusterRoles().Delete(ctx, roleName, metav1.DeleteOptions{})
+	})
+
+	roleBinding := &rbacv1.RoleBinding{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      roleName,
+			Namespace: sourceNamespace,
+		},
+		RoleRef: rbacv1.RoleRef{
+			APIGroup: rbacv1.GroupName,
+			Kind:     "ClusterRole",
+			Name:     roleName,
+		},
+		Subjects: []rbacv1.Subject{
+			{
+				Kind:      rbacv1.ServiceAccountKind,
+				Name:      "default",
+				Namespace: consumingNamespace,
+			},
+		},
+	}
+	_, err = f.ClientSet.RbacV1().RoleBindings(sourceNamespace).Create(ctx, roleBinding, metav1.CreateOptions{})
+	framework.ExpectNoError(err, "failed to create RoleBinding %q in namespace %q", roleName, sourceNamespace)
+}
+
+// podWithCrossNamespaceSecretVolume returns a pod referencing secretName in sourceNamespace
+// through a SecretVolumeSource with SourceNamespace set, mounted the same way as the
+// same-namespace secret volume tests above.
+func podWithCrossNamespaceSecretVolume(secretName, sourceNamespace string) *v1.Pod {
+	volumeName := "secret-volume"
+	volumeMountPath := "/etc/secret-volume"
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "pod-secrets-" + string(uuid.NewUUID()),
+		},
+		Spec: v1.PodSpec{
+			Volumes: []v1.Volume{
+				{
+					Name: volumeName,
+					VolumeSource: v1.VolumeSource{
+						Secret: &v1.SecretVolumeSource{
+							SecretName:      secretName,
+							SourceNamespace: &sourceNamespace,
+						},
+					},
+				},
+			},
+			Containers: []v1.Container{
+				{
+					Name:  "secret-volume-test",
+					Image: imageutils.GetE2EImage(imageutils.Agnhost),
+					Args: []string{
+						"mounttest",
+						"--file_content=/etc/secret-volume/data-1",
+						"--file_mode=/etc/secret-volume/data-1"},
+					VolumeMounts: []v1.VolumeMount{
+						{
+							Name:      volumeName,
+							MountPath: volumeMountPath,
+						},
+					},
+				},
+			},
+			RestartPolicy: v1.RestartPolicyNever,
+		},
+	}
+}
+
+// mockSecretCSIDriverImage is the hermetic, in-tree CSI driver built from
+// test/images/mock-secret-csi-driver that backs the "[Feature:CSIEphemeralSecret]" suite. It
+// actually speaks the CSI Identity/Node gRPC protocol over the kubelet plugin socket -- unlike
+// a plain HTTP stand-in, the kubelet can genuinely call NodePublishVolume against it.
+const mockSecretCSIDriverImage = "registry.k8s.io/e2e-test-images/mock-secret-csi-driver:1.0"
+
+const csiNodeDriverRegistrarImage = "registry.k8s.io/sig-storage/csi-node-driver-registrar:v2.10.0"
+
+// mockSecretProviderFixture drives a hermetic stand-in for a cloud KMS/Vault-style CSI
+// secret provider. It is deployed as a DaemonSet in the test namespace, registered with the
+// cluster via a CSIDriver object, and plugged into each node's kubelet through the standard
+// CSI node-driver-registrar sidecar -- exactly how an out-of-tree ephemeral CSI driver gets
+// installed in a real cluster -- so that CSI inline volumes naming its driver actually mount.
+type mockSecretProviderFixture struct {
+	f            *framework.Framework
+	providerName string
+	driverName   string
+	daemonSet    *appsv1.DaemonSet
+	driverObj    *storagev1.CSIDriver
+}
+
+func newMockSecretProviderFixture(f *framework.Framework) *mockSecretProviderFixture {
+	suffix := string(uuid.NewUUID())
+	return &mockSecretProviderFixture{
+		f:            f,
+		providerName: "mock-" + suffix,
+		driverName:   "mock-" + suffix + ".csi.secrets-store.k8s.io",
+	}
+}
+
+// deploy registers the CSIDriver object, stands up the driver DaemonSet (driver + registrar
+// sidecar), and waits for it to become ready on every node.
+func (m *mockSecretProviderFixture) deploy(ctx context.Context) {
+	ginkgo.By(fmt.Sprintf("Registering CSIDriver %s", m.driverName))
+	ephemeral := storagev1.VolumeLifecycleEphemeral
+	podInfoOnMount := true
+	m.driverObj = &storagev1.CSIDriver{
+		ObjectMeta: metav1.ObjectMeta{Name: m.driverName},
+		Spec: storagev1.CSIDriverSpec{
+			PodInfoOnMount:       &podInfoOnMount,
+			VolumeLifecycleModes: []storagev1.VolumeLifecycleMode{ephemeral},
+		},
+	}
+	var err error
+	m.driverObj, err = m.f.ClientSet.StorageV1().CSIDrivers().Create(ctx, m.driverObj, metav1.CreateOptions{})
+	framework.ExpectNoError(err, "failed to register CSIDriver %q", m.driverName)
+
+	ginkgo.By(fmt.Sprintf("Deploying mock secret provider %s", m.providerName))
+	hostPathType := v1.HostPathDirectoryOrCreate
+	pluginDir := "/var/lib/kubelet/plugins/" + m.driverName
+	registrationDir := "/var/lib/kubelet/plugins_registry"
+	m.daemonSet = &appsv1.DaemonSet{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      "mock-secret-provider-" + m.providerName,
+			Namespace: m.f.Namespace.Name,
+		},
+		Spec: appsv1.DaemonSetSpec{
+			Selector: &metav1.LabelSelector{
+				MatchLabels: map[string]string{"app": m.providerName},
+			},
+			Template: v1.PodTemplateSpec{
+				ObjectMeta: metav1.ObjectMeta{
+					Labels: map[string]string{"app": m.providerName},
+				},
+				Spec: v1.PodSpec{
+					Containers: []v1.Container{
+						{
+							Name:  "mock-provider",
+							Image: mockSecretCSIDriverImage,
+							Args: []string{
+								"--csi-address=/csi/csi.sock",
+								"--admin-address=:8080",
+								"--driver-name=" + m.driverName,
+							},
+							Ports: []v1.ContainerPort{{ContainerPort: 8080, Name: "admin"}},
+							VolumeMounts: []v1.VolumeMount{
+								{Name: "plugin-dir", MountPath: "/csi"},
+							},
+						},
+						{
+							Name:  "node-driver-registrar",
+							Image: csiNodeDriverRegistrarImage,
+							Args: []string{
+								"--csi-address=/csi/csi.sock",
+								"--kubelet-registration-path=" + pluginDir + "/csi.sock",
+							},
+							VolumeMounts: []v1.VolumeMount{
+								{Name: "plugin-dir", MountPath: "/csi"},
+								{Name: "registration-dir", MountPath: "/registration"},
+							},
+						},
+					},
+					Volumes: []v1.Volume{
+						{Name: "plugin-dir", VolumeSource: v1.VolumeSource{HostPath: &v1.HostPathVolumeSource{Path: pluginDir, Type: &hostPathType}}},
+						{Name: "registration-dir", VolumeSource: v1.VolumeSource{HostPath: &v1.HostPathVolumeSource{Path: registrationDir, Type: &hostPathType}}},
+					},
+				},
+			},
+		},
+	}
+	m.daemonSet, err = m.f.ClientSet.AppsV1().DaemonSets(m.f.Namespace.Name).Create(ctx, m.daemonSet, metav1.CreateOptions{})
+	framework.ExpectNoError(err, "failed to create mock secret provider DaemonSet")
+	framework.ExpectNoError(e2epod.WaitForPodsRunningReady(ctx, m.f.ClientSet, m.f.Namespace.Name, 1, 0, framework.PodStartTimeout))
+}
+
+// seed pushes the given key/value pairs into the driver's in-memory store by proxying an HTTP
+// POST to its admin port through the API server, so the next CSI NodePublishVolume/rotation
+// poll for m.providerName observes them.
+func (m *mockSecretProviderFixture) seed(ctx context.Context, data map[string]string) {
+	ginkgo.By(fmt.Sprintf("Seeding mock secret provider %s with %d keys", m.providerName, len(data)))
+	pods, err := m.f.ClientSet.CoreV1().Pods(m.f.Namespace.Name).List(ctx, metav1.ListOptions{
+		LabelSelector: fields.SelectorFromSet(fields.Set{"app": m.providerName}).String(),
+	})
+	framework.ExpectNoError(err, "failed to list mock secret provider pods")
+	if len(pods.Items) == 0 {
+		framework.Failf("no mock secret provider pods found for %s", m.providerName)
+	}
+
+	body, err := json.Marshal(struct {
+		Provider string            `json:"provider"`
+		Data     map[string]string `json:"data"`
+	}{Provider: m.providerName, Data: data})
+	framework.ExpectNoError(err, "failed to marshal mock secret provider seed payload")
+
+	// Each DaemonSet pod keeps its own in-memory store, so every node's driver is seeded.
+	for _, pod := range pods.Items {
+		result := m.f.ClientSet.CoreV1().RESTClient().Post().
+			Namespace(m.f.Namespace.Name).
+			Resource("pods").
+			Name(fmt.Sprintf("%s:8080", pod.Name)).
+			SubResource("proxy").
+			Suffix("seed").
+			Body(body).
+			Do(ctx)
+		framework.ExpectNoError(result.Error(), "failed to seed mock secret provider pod %s", pod.Name)
+	}
+}
+
+func (m *mockSecretProviderFixture) cleanup(ctx context.Context) {
+	if m.daemonSet != nil {
+		_ = m.f.ClientSet.AppsV1().DaemonSets(m.f.Namespace.Name).Delete(ctx, m.daemonSet.Name, metav1.DeleteOptions{})
+	}
+	if m.driverObj != nil {
+		_ = m.f.ClientSet.StorageV1().CSIDrivers().Delete(ctx, m.driverObj.Name, metav1.DeleteOptions{})
+	}
+}
+
+// podWithEphemeralProviderVolume returns a pod that mounts an ephemeral CSI volume backed by
+// driver, passing attributes through as the volume's CSI VolumeAttributes, analogous to how a
+// SecretProviderClass wires a provider name and parameters to the secrets-store-csi driver.
+func podWithEphemeralProviderVolume(driver, volumeName string, attributes map[string]string, readOnly *bool) *v1.Pod {
+	mountPath := "/mnt/secrets-store"
+	return &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "pod-ephemeral-secret-" + string(uuid.NewUUID()),
+		},
+		Spec: v1.PodSpec{
+			Volumes: []v1.Volume{
+				{
+					Name: volumeName,
+					VolumeSource: v1.VolumeSource{
+						CSI: &v1.CSIVolumeSource{
+							Driver:           driver,
+							ReadOnly:         readOnly,
+							VolumeAttributes: attributes,
+						},
+					},
+				},
+			},
+			Containers: []v1.Container{
+				{
+					Name:  "secret-volume-test",
+					Image: imageutils.GetE2EImage(imageutils.Agnhost),
+					Args: []string{
+						"mounttest",
+						fmt.Sprintf("--file_content=%s", path.Join(mountPath, "data-1")),
+						fmt.Sprintf("--file_content=%s", path.Join(mountPath, "data-2")),
+					},
+					VolumeMounts: []v1.VolumeMount{
+						{
+							Name:      volumeName,
+							MountPath: mountPath,
+							ReadOnly:  true,
+						},
+					},
+				},
+			},
+			RestartPolicy: v1.RestartPolicyNever,
+		},
+	}
+}
+
+// doSecretE2EWithSubPath mounts a single secret key at a fixed path using subPath (or
+// subPathExpr, resolved from a downward API environment variable, when expr is true), then
+// updates the secret and asserts the subPath-mounted file is NOT updated within
+// GetPodSecretUpdateTimeout (see the package doc in k8s.io/kubernetes/pkg/volume/secret for why).
+func doSecretE2EWithSubPath(ctx context.Context, f *framework.Framework, expr bool) {
+	var (
+		name            = "secret-test-subpath-" + string(uuid.NewUUID())
+		volumeName      = "secret-volume"
+		volumeMountPath = "/etc/secret-volume"
+		secret          = secretForTest(f.Namespace.Name, name)
+	)
+
+	ginkgo.By(fmt.Sprintf("Creating secret with name %s", secret.Name))
+	var err error
+	if secret, err = f.ClientSet.CoreV1().Secrets(f.Namespace.Name).Create(ctx, secret, metav1.CreateOptions{}); err != nil {
+		framework.Failf("unable to create test secret %s: %v", secret.Name, err)
+	}
+
+	podName := "pod-secrets-subpath-" + string(uuid.NewUUID())
+	mountedFile := "foo.conf"
+	volumeMount := v1.VolumeMount{
+		Name:      volumeName,
+		MountPath: path.Join(volumeMountPath, mountedFile),
+		SubPath:   "data-1",
+	}
+	var envVars []v1.EnvVar
+	if expr {
+		mountedFile = podName + ".conf"
+		volumeMount.MountPath = path.Join(volumeMountPath, mountedFile)
+		volumeMount.SubPath = ""
+		volumeMount.SubPathExpr = "$(POD_NAME).conf"
+		envVars = []v1.EnvVar{
+			{
+				Name:      "POD_NAME",
+				ValueFrom: &v1.EnvVarSource{FieldRef: &v1.ObjectFieldSelector{FieldPath: "metadata.name"}},
+			},
+		}
+	}
+
+	podLogTimeout := e2epod.GetPodSecretUpdateTimeout(ctx, f.ClientSet)
+	containerTimeoutArg := fmt.Sprintf("--retry_time=%v", int(podLogTimeout.Seconds()))
+
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: podName,
+		},
+		Spec: v1.PodSpec{
+			Volumes: []v1.Volume{
+				{
+					Name: volumeName,
+					VolumeSource: v1.VolumeSource{
+						Secret: &v1.SecretVolumeSource{
+							SecretName: name,
+						},
+					},
+				},
+			},
+			Containers: []v1.Container{
+				{
+					Name:         "secret-volume-test",
+					Image:        imageutils.GetE2EImage(imageutils.Agnhost),
+					Env:          envVars,
+					Args:         []string{"mounttest", "--break_on_expected_content=false", containerTimeoutArg, "--file_content_in_loop=" + path.Join(volumeMountPath, mountedFile)},
+					VolumeMounts: []v1.VolumeMount{volumeMount},
+				},
+			},
+			RestartPolicy: v1.RestartPolicyNever,
+		},
+	}
+	if expr {
+		// subPathExpr resolves to "<pod name>.conf", so data-1 is projected under that name.
+		pod.Spec.Volumes[0].Secret.Items = []v1.KeyToPath{{Key: "data-1", Path: mountedFile}}
+	}
+
+	ginkgo.By("Creating the pod")
+	podClient := e2epod.NewPodClient(f)
+	podClient.CreateSync(ctx, pod)
+
+	podLogs := func() (string, error) {
+		return e2epod.GetPodLogs(ctx, f.ClientSet, f.Namespace.Name, pod.Name, pod.Spec.Containers[0].Name)
+	}
+	gomega.Eventually(ctx, podLogs, podLogTimeout, framework.Poll).Should(gomega.ContainSubstring("value-1"))
+
+	ginkgo.By(fmt.Sprintf("Updating secret %v", secret.Name))
+	secret, err = f.ClientSet.CoreV1().Secrets(f.Namespace.Name).Get(ctx, secret.Name, metav1.GetOptions{})
+	framework.ExpectNoError(err, "failed to get secret %q", secret.Name)
+	secret.Data["data-1"] = []byte("value-updated")
+	_, err = f.ClientSet.CoreV1().Secrets(f.Namespace.Name).Update(ctx, secret, metav1.UpdateOptions{})
+	framework.ExpectNoError(err, "failed to update secret %q", secret.Name)
+
+	ginkgo.By("Asserting the subPath-mounted file keeps serving its original content")
+	gomega.Consistently(ctx, podLogs, podLogTimeout, framework.Poll).ShouldNot(gomega.ContainSubstring("value-updated"))
+}
+
+// getFileOwnerRegex returns a regexp matching the mounttest "--file_owner" output for
+// filePath when owned by uid/gid, mirroring how getFileModeRegex matches "--file_mode" output.
+func getFileOwnerRegex(filePath string, uid, gid int64) string {
+	return fmt.Sprintf("owner UID of file \"%s\": %d\\s*\\nowner GID of file \"%s\": %d", filePath, uid, filePath, gid)
+}
+
+// maxSecretSizeBytes mirrors the apiserver's per-secret size limit enforced in validation.
+const maxSecretSizeBytes = 1024 * 1024
+
+// binaryTestPayload returns a deterministic, non-ASCII byte slice of the given length,
+// cycling through every byte value (including NUL and high-bit bytes) so the fidelity
+// check below actually stresses the atomic writer rather than just printable ASCII.
+func binaryTestPayload(size int) []byte {
+	payload := make([]byte, size)
+	for i := range payload {
+		payload[i] = byte(i % 256)
+	}
+	return payload
+}
+
+// doSecretE2EWithBinaryData creates a secret containing an arbitrary-byte value, mounts it,
+// and has the container sha256sum the projected file so fidelity is verified without ever
+// printing the binary payload itself through the test framework's regexp log matching.
+func doSecretE2EWithBinaryData(ctx context.Context, f *framework.Framework, name string, payload []byte) {
+	var (
+		volumeName      = "secret-volume"
+		volumeMountPath = "/etc/secret-volume"
+		filePath        = path.Join(volumeMountPath, "data-1")
+	)
+
+	secret := secretForTest(f.Namespace.Name, name)
+	secret.Data = map[string][]byte{"data-1": payload}
+
+	ginkgo.By(fmt.Sprintf("Creating secret with name %s (%d bytes)", secret.Name, len(payload)))
+	var err error
+	if secret, err = f.ClientSet.CoreV1().Secrets(f.Namespace.Name).Create(ctx, secret, metav1.CreateOptions{}); err != nil {
+		framework.Failf("unable to create test secret %s: %v", secret.Name, err)
+	}
+
+	sum := sha256.Sum256(payload)
+	expectedDigest := hex.EncodeToString(sum[:])
+
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "pod-secrets-binary-" + string(uuid.NewUUID()),
+		},
+		Spec: v1.PodSpec{
+			Volumes: []v1.Volume{
+				{
+					Name: volumeName,
+					VolumeSource: v1.VolumeSource{
+						Secret: &v1.SecretVolumeSource{
+							SecretName: name,
+						},
+					},
+				},
+			},
+			Containers: []v1.Container{
+				{
+					Name:    "secret-volume-test",
+					Image:   imageutils.GetE2EImage(imageutils.Agnhost),
+					Command: []string{"sh", "-c"},
+					Args:    []string{fmt.Sprintf("sha256sum %s", filePath)},
+					VolumeMounts: []v1.VolumeMount{
+						{
+							Name:      volumeName,
+							MountPath: volumeMountPath,
+							ReadOnly:  true,
+						},
+					},
+				},
+			},
+			RestartPolicy: v1.RestartPolicyNever,
+		},
+	}
+
+	e2epodoutput.TestContainerOutputRegexp(ctx, f, "sha256 of binary secret data", pod, 0, []string{expectedDigest})
+}
+
+// recordSecretProjectionMetrics mounts a secret with keyCount keys of valueSize bytes each,
+// recording projection time (pod start to first successful read) and update-propagation
+// latency via framework.RecordMetric so regressions in the atomic writer are visible across
+// runs rather than only causing a binary pass/fail.
+func recordSecretProjectionMetrics(ctx context.Context, f *framework.Framework, keyCount, valueSize int) {
+	name := fmt.Sprintf("secret-perf-%d-%d-%s", keyCount, valueSize, string(uuid.NewUUID()))
+	volumeMountPath := "/etc/secret-volume"
+
+	data := make(map[string][]byte, keyCount)
+	keys := make([]string, 0, keyCount)
+	for i := 0; i < keyCount; i++ {
+		key := fmt.Sprintf("data-%d", i)
+		keys = append(keys, key)
+		data[key] = binaryTestPayload(valueSize)
+	}
+
+	secret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: f.Namespace.Name, Name: name},
+		Data:       data,
+	}
+	ginkgo.By(fmt.Sprintf("Creating secret %s with %d keys of %d bytes", name, keyCount, valueSize))
+	var err error
+	if secret, err = f.ClientSet.CoreV1().Secrets(f.Namespace.Name).Create(ctx, secret, metav1.CreateOptions{}); err != nil {
+		framework.Failf("unable to create test secret %s: %v", secret.Name, err)
+	}
+
+	podLogTimeout := e2epod.GetPodSecretUpdateTimeout(ctx, f.ClientSet)
+	containerTimeoutArg := fmt.Sprintf("--retry_time=%v", int(podLogTimeout.Seconds()))
+
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{Name: "pod-secrets-perf-" + string(uuid.NewUUID())},
+		Spec: v1.PodSpec{
+			Volumes: []v1.Volume{
+				{
+					Name:         "secret-volume",
+					VolumeSource: v1.VolumeSource{Secret: &v1.SecretVolumeSource{SecretName: name}},
+				},
+			},
+			Containers: []v1.Container{
+				{
+					Name:  "secret-volume-test",
+					Image: imageutils.GetE2EImage(imageutils.Agnhost),
+					Args:  []string{"mounttest", "--break_on_expected_content=false", containerTimeoutArg, "--file_content_in_loop=" + path.Join(volumeMountPath, keys[0])},
+					VolumeMounts: []v1.VolumeMount{
+						{Name: "secret-volume", MountPath: volumeMountPath, ReadOnly: true},
+					},
+				},
+			},
+			RestartPolicy: v1.RestartPolicyNever,
+		},
+	}
+
+	ginkgo.By("Creating the pod and measuring projection time")
+	podClient := e2epod.NewPodClient(f)
+	projectionStart := time.Now()
+	podClient.CreateSync(ctx, pod)
+
+	podLogs := func() (string, error) {
+		return e2epod.GetPodLogs(ctx, f.ClientSet, f.Namespace.Name, pod.Name, pod.Spec.Containers[0].Name)
+	}
+	gomega.Eventually(ctx, podLogs, podLogTimeout, framework.Poll).Should(gomega.ContainSubstring(fmt.Sprintf("content of file \"%s\"", path.Join(volumeMountPath, keys[0]))))
+	projectionDuration := time.Since(projectionStart)
+
+	metricLabel := fmt.Sprintf("keys=%d,size=%d", keyCount, valueSize)
+	framework.RecordMetric(metricLabel+",projection", fmt.Sprintf("%v", projectionDuration))
+
+	ginkgo.By("Updating the secret and measuring propagation latency")
+	secret, err = f.ClientSet.CoreV1().Secrets(f.Namespace.Name).Get(ctx, secret.Name, metav1.GetOptions{})
+	framework.ExpectNoError(err, "failed to get secret %q", secret.Name)
+	delete(secret.Data, keys[0])
+	updateStart := time.Now()
+	_, err = f.ClientSet.CoreV1().Secrets(f.Namespace.Name).Update(ctx, secret, metav1.UpdateOptions{})
+	framework.ExpectNoError(err, "failed to update secret %q", secret.Name)
+
+	gomega.Eventually(ctx, podLogs, podLogTimeout, framework.Poll).Should(gomega.ContainSubstring("Error reading file"))
+	framework.RecordMetric(metricLabel+",update-propagation", fmt.Sprintf("%v", time.Since(updateStart)))
+}
+
+// vaultFixture runs a HashiCorp Vault dev server in the test namespace and syncs seeded KV
+// paths into regular Kubernetes Secrets, standing in for a production token-auth sync
+// controller so the rest of the suite can assert on the resulting Secret like any other.
+type vaultFixture struct {
+	f       *framework.Framework
+	podName string
+	addr    string
+}
+
+func newVaultFixture(f *framework.Framework) *vaultFixture {
+	podName := "vault-dev-" + string(uuid.NewUUID())
+	return &vaultFixture{
+		f:       f,
+		podName: podName,
+		addr:    fmt.Sprintf("http://%s.%s.svc.cluster.local:8200", podName, f.Namespace.Name),
+	}
+}
+
+// deploy starts a Vault dev-mode server pod and waits for it to become ready.
+func (v *vaultFixture) deploy(ctx context.Context) {
+	ginkgo.By(fmt.Sprintf("Deploying Vault dev server %s", v.podName))
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name:      v.podName,
+			Namespace: v.f.Namespace.Name,
+			Labels:    map[string]string{"app": "vault-dev"},
+		},
+		Spec: v1.PodSpec{
+			Containers: []v1.Container{
+				{
+					Name:  "vault",
+					Image: "hashicorp/vault:1.15",
+					Env: []v1.EnvVar{
+						{Name: "VAULT_DEV_ROOT_TOKEN_ID", Value: "root"},
+						{Name: "VAULT_ADDR", Value: "http://127.0.0.1:8200"},
+					},
+					Ports: []v1.ContainerPort{{ContainerPort: 8200}},
+				},
+			},
+			RestartPolicy: v1.RestartPolicyNever,
+		},
+	}
+	e2epod.NewPodClient(v.f).CreateSync(ctx, pod)
+}
+
+// seed writes the given key/value pairs to secretPath inside the dev server via `vault kv put`.
+func (v *vaultFixture) seed(ctx context.Context, secretPath string, data map[string]string) {
+	ginkgo.By(fmt.Sprintf("Seeding Vault path %s", secretPath))
+	args := []string{"kv", "put", secretPath}
+	for k, val := range data {
+		args = append(args, fmt.Sprintf("%s=%s", k, val))
+	}
+	_, _, err := e2epod.ExecCommandInContainerWithFullOutput(v.f, v.podName, "vault", append([]string{"vault"}, args...)...)
+	framework.ExpectNoError(err, "failed to seed Vault path %q", secretPath)
+}
+
+// syncToSecret reads secretPath back from Vault and materializes it as a Kubernetes Secret,
+// failing the test if the sync does not succeed.
+func (v *vaultFixture) syncToSecret(ctx context.Context, secretPath, namespace, secretName string) {
+	framework.ExpectNoError(v.trySyncToSecret(ctx, secretPath, namespace, secretName), "failed to sync Vault path %q into Secret %q", secretPath, secretName)
+}
+
+// trySyncToSecret is the non-fatal counterpart of syncToSecret, used by the negative test to
+// assert a missing Vault path surfaces as an error instead of silently creating an empty Secret.
+func (v *vaultFixture) trySyncToSecret(ctx context.Context, secretPath, namespace, secretName string) error {
+	out, _, err := e2epod.ExecCommandInContainerWithFullOutput(v.f, v.podName, "vault", "vault", "kv", "get", "-format=json", secretPath)
+	if err != nil {
+		return fmt.Errorf("vault kv get %s: %w", secretPath, err)
+	}
+
+	data, err := parseVaultKVGetJSON(out)
+	if err != nil {
+		return fmt.Errorf("parsing vault kv get output for %s: %w", secretPath, err)
+	}
+
+	secret := &v1.Secret{
+		ObjectMeta: metav1.ObjectMeta{Namespace: namespace, Name: secretName},
+		Data:       data,
+	}
+	_, err = v.f.ClientSet.CoreV1().Secrets(namespace).Create(ctx, secret, metav1.CreateOptions{})
+	return err
+}
+
+func (v *vaultFixture) cleanup(ctx context.Context) {
+	_ = v.f.ClientSet.CoreV1().Pods(v.f.Namespace.Name).Delete(ctx, v.podName, metav1.DeleteOptions{})
+}
+
+// parseVaultKVGetJSON extracts the data.data map from `vault kv get -format=json` output.
+func parseVaultKVGetJSON(out string) (map[string][]byte, error) {
+	var resp struct {
+		Data struct {
+			Data map[string]string `json:"data"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal([]byte(out), &resp); err != nil {
+		return nil, err
+	}
+	data := make(map[string][]byte, len(resp.Data.Data))
+	for k, v := range resp.Data.Data {
+		data[k] = []byte(v)
+	}
+	return data, nil
+}
+
+// doSecretE2EWithMappingEncoding mirrors doSecretE2EWithMapping, except the Secret stores an
+// encoded binary payload and the projected KeyToPath requests the matching Encoding so the
+// kubelet decodes it before writing the file. It checks the sha256 of the projected file
+// against the decoded payload; the file mode is not checked.
+func doSecretE2EWithMappingEncoding(ctx context.Context, f *framework.Framework, encoding v1.SecretKeyToPathEncoding) {
+	var (
+		name            = "secret-test-map-encoded-" + string(uuid.NewUUID())
+		volumeName      = "secret-volume"
+		volumeMountPath = "/etc/secret-volume"
+		decoded         = binaryTestPayload(256)
+	)
+
+	var encodedValue []byte
+	switch encoding {
+	case v1.SecretKeyToPathEncodingBase64:
+		encodedValue = []byte(base64.StdEncoding.EncodeToString(decoded))
+	case v1.SecretKeyToPathEncodingHex:
+		encodedValue = []byte(hex.EncodeToString(decoded))
+	default:
+		framework.Failf("unsupported encoding %q for this helper", encoding)
+	}
+
+	secret := secretForTest(f.Namespace.Name, name)
+	secret.Data = map[string][]byte{"data-1": encodedValue}
+
+	ginkgo.By(fmt.Sprintf("Creating secret with name %s", secret.Name))
+	var err error
+	if secret, err = f.ClientSet.CoreV1().Secrets(f.Namespace.Name).Create(ctx, secret, metav1.CreateOptions{}); err != nil {
+		framework.Failf("unable to create test secret %s: %v", secret.Name, err)
+	}
+
+	sum := sha256.Sum256(decoded)
+	expectedDigest := hex.EncodeToString(sum[:])
+	filePath := path.Join(volumeMountPath, "new-path-data-1")
+
+	pod := &v1.Pod{
+		ObjectMeta: metav1.ObjectMeta{
+			Name: "pod-secrets-" + string(uuid.NewUUID()),
+		},
+		Spec: v1.PodSpec{
+			Volumes: []v1.Volume{
+				{
+					Name: volumeName,
+					VolumeSource: v1.VolumeSource{
+						Secret: &v1.SecretVolumeSource{
+							SecretName: name,
+							Items: []v1.KeyToPath{
+								{
+									Key:      "data-1",
+									Path:     "new-path-data-1",
+									Encoding: encoding,
+								},
+							},
+						},
+					},
+				},
+			},
+			Containers: []v1.Container{
+				{
+					Name:    "secret-volume-test",
+					Image:   imageutils.GetE2EImage(imageutils.Agnhost),
+					Command: []string{"sh", "-c"},
+					Args:    []string{fmt.Sprintf("sha256sum %s", filePath)},
+					VolumeMounts: []v1.VolumeMount{
+						{
+							Name:      volumeName,
+							MountPath: volumeMountPath,
+						},
+					},
+				},
+			},
+			RestartPolicy: v1.RestartPolicyNever,
+		},
+	}
+
+	e2epodoutput.TestContainerOutputRegexp(ctx, f, fmt.Sprintf("consume %s-decoded secret", encoding), pod, 0, []string{expectedDigest})
+}
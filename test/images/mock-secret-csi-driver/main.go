@@ -0,0 +1,200 @@
+/*
+Copyright 2014 The Kubernetes Authors.
+
+Licensed under the Apache License, Version 2.0 (the "License");
+you may not use this file except in compliance with the License.
+You may obtain a copy of the License at
+
+    http://www.apache.org/licenses/LICENSE-2.0
+
+Unless required by applicable law or agreed to in writing, software
+distributed under the License is distributed on an "AS IS" BASIS,
+WITHOUT WARRANTIES OR CONDITIONS OF ANY KIND, either express or implied.
+See the License for the specific language governing permissions and
+limitations under the License.
+*/
+
+// Command mock-secret-csi-driver is a hermetic, in-tree stand-in for a
+// SecretProviderClass-style external-secret-store CSI driver, used only by the
+// "[Feature:CSIEphemeralSecret]" e2e suite in
+// test/e2e/common/storage/secrets_volume.go. It implements just enough of the CSI Identity and
+// Node services to satisfy kubelet's ephemeral inline-volume path, plus a small admin HTTP API
+// the e2e test uses to seed and rotate the provider's in-memory key/value store.
+package main
+
+import (
+	"context"
+	"encoding/json"
+	"flag"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/container-storage-interface/spec/lib/go/csi"
+	"google.golang.org/grpc"
+	"k8s.io/client-go/kubernetes"
+	"k8s.io/client-go/rest"
+	"k8s.io/kubernetes/pkg/volume/csi/secretsync"
+)
+
+var (
+	csiSocket  = flag.String("csi-address", "/csi/csi.sock", "CSI gRPC unix socket to serve on")
+	adminAddr  = flag.String("admin-address", ":8080", "HTTP address the e2e test uses to seed/rotate provider data")
+	driverName = flag.String("driver-name", "mock.csi.secrets-store.k8s.io", "driver name reported via GetPluginInfo")
+)
+
+// store holds, per provider name, the key/value pairs the next NodePublishVolume for that
+// provider should materialize as files.
+type store struct {
+	mu   sync.RWMutex
+	data map[string]map[string]string
+}
+
+func newStore() *store {
+	return &store{data: map[string]map[string]string{}}
+}
+
+func (s *store) set(provider string, data map[string]string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data[provider] = data
+}
+
+func (s *store) get(provider string) map[string]string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return s.data[provider]
+}
+
+type identityServer struct {
+	csi.UnimplementedIdentityServer
+	name string
+}
+
+func (i *identityServer) GetPluginInfo(ctx context.Context, _ *csi.GetPluginInfoRequest) (*csi.GetPluginInfoResponse, error) {
+	return &csi.GetPluginInfoResponse{Name: i.name, VendorVersion: "e2e"}, nil
+}
+
+func (i *identityServer) GetPluginCapabilities(ctx context.Context, _ *csi.GetPluginCapabilitiesRequest) (*csi.GetPluginCapabilitiesResponse, error) {
+	return &csi.GetPluginCapabilitiesResponse{}, nil
+}
+
+func (i *identityServer) Probe(ctx context.Context, _ *csi.ProbeRequest) (*csi.ProbeResponse, error) {
+	return &csi.ProbeResponse{}, nil
+}
+
+type nodeServer struct {
+	csi.UnimplementedNodeServer
+	store      *store
+	kubeClient kubernetes.Interface
+}
+
+// NodePublishVolume materializes the seeded provider data as files at the target path, then
+// -- when the volume context requests it via secretsync.SyncToSecretAttribute -- mirrors the
+// mounted files into a Kubernetes Secret.
+func (n *nodeServer) NodePublishVolume(ctx context.Context, req *csi.NodePublishVolumeRequest) (*csi.NodePublishVolumeResponse, error) {
+	attrs := req.GetVolumeContext()
+	data := n.store.get(attrs["provider"])
+
+	if err := os.MkdirAll(req.GetTargetPath(), 0755); err != nil {
+		return nil, err
+	}
+	for key, value := range data {
+		if err := os.WriteFile(filepath.Join(req.GetTargetPath(), key), []byte(value), 0440); err != nil {
+			return nil, err
+		}
+	}
+
+	if pollSecs, ok := attrs["pollIntervalSecs"]; ok && pollSecs != "" {
+		go n.pollForRotation(req.GetTargetPath(), attrs["provider"], pollSecs)
+	}
+
+	if n.kubeClient != nil {
+		podNamespace := attrs["csi.storage.k8s.io/pod.namespace"]
+		if err := secretsync.Sync(ctx, n.kubeClient, podNamespace, req.GetTargetPath(), attrs); err != nil {
+			return nil, err
+		}
+	}
+
+	return &csi.NodePublishVolumeResponse{}, nil
+}
+
+// pollForRotation re-materializes the target path on the requested interval so that updates
+// seeded after the initial mount (see the mock provider's /seed admin endpoint) show up in a
+// running container without requiring a remount, mirroring a real provider's rotation poll.
+func (n *nodeServer) pollForRotation(targetPath, provider, pollSecs string) {
+	interval, err := time.ParseDuration(pollSecs + "s")
+	if err != nil {
+		return
+	}
+	for range time.Tick(interval) {
+		data := n.store.get(provider)
+		for key, value := range data {
+			_ = os.WriteFile(filepath.Join(targetPath, key), []byte(value), 0440)
+		}
+	}
+}
+
+func (n *nodeServer) NodeUnpublishVolume(ctx context.Context, req *csi.NodeUnpublishVolumeRequest) (*csi.NodeUnpublishVolumeResponse, error) {
+	if err := os.RemoveAll(req.GetTargetPath()); err != nil && !os.IsNotExist(err) {
+		return nil, err
+	}
+	return &csi.NodeUnpublishVolumeResponse{}, nil
+}
+
+func (n *nodeServer) NodeGetCapabilities(ctx context.Context, _ *csi.NodeGetCapabilitiesRequest) (*csi.NodeGetCapabilitiesResponse, error) {
+	return &csi.NodeGetCapabilitiesResponse{}, nil
+}
+
+func (n *nodeServer) NodeGetInfo(ctx context.Context, _ *csi.NodeGetInfoRequest) (*csi.NodeGetInfoResponse, error) {
+	nodeID, _ := os.Hostname()
+	return &csi.NodeGetInfoResponse{NodeId: nodeID}, nil
+}
+
+// serveAdminAPI exposes the /seed endpoint the e2e test's mockSecretProviderFixture.seed uses
+// to push {provider, data} into the in-memory store ahead of the next mount or rotation poll.
+func serveAdminAPI(s *store) {
+	mux := http.NewServeMux()
+	mux.HandleFunc("/seed", func(w http.ResponseWriter, r *http.Request) {
+		var req struct {
+			Provider string            `json:"provider"`
+			Data     map[string]string `json:"data"`
+		}
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		s.set(req.Provider, req.Data)
+		w.WriteHeader(http.StatusNoContent)
+	})
+	log.Fatal(http.ListenAndServe(*adminAddr, mux))
+}
+
+func main() {
+	flag.Parse()
+
+	s := newStore()
+	go serveAdminAPI(s)
+
+	var kubeClient kubernetes.Interface
+	if cfg, err := rest.InClusterConfig(); err == nil {
+		if c, err := kubernetes.NewForConfig(cfg); err == nil {
+			kubeClient = c
+		}
+	}
+
+	_ = os.Remove(*csiSocket)
+	listener, err := net.Listen("unix", *csiSocket)
+	if err != nil {
+		log.Fatalf("failed to listen on %s: %v", *csiSocket, err)
+	}
+
+	server := grpc.NewServer()
+	csi.RegisterIdentityServer(server, &identityServer{name: *driverName})
+	csi.RegisterNodeServer(server, &nodeServer{store: s, kubeClient: kubeClient})
+	log.Fatal(server.Serve(listener))
+}